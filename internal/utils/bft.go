@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+)
+
+// BFTOptions carries the SmartBFT consensus-type options Fabric 3.x
+// expects under ConsensusType.metadata.options when consensusType is "BFT".
+type BFTOptions struct {
+	RequestBatchMaxCount      int
+	RequestBatchMaxBytes      string
+	RequestBatchMaxInterval   string
+	IncomingMessageBufferSize int
+	RequestPoolSize           int
+	RequestForwardTimeout     string
+	RequestComplainTimeout    string
+	RequestAutoRemoveTimeout  string
+	ViewChangeResendInterval  string
+	ViewChangeTimeout         string
+	LeaderHeartbeatTimeout    string
+	LeaderHeartbeatCount      int
+	CollectTimeout            string
+	SyncOnStart               bool
+	SpeedUpViewChange         bool
+	LeaderRotation            string
+	DecisionsPerLeader        int
+}
+
+// BFTConsenter describes one orderer node in a SmartBFT consenter set. Unlike
+// RaftConsenter it carries an MSP ID and identity alongside the TLS material.
+// GetBFTConsenterModifiedConfig removes entries by host and port match only.
+type BFTConsenter struct {
+	Host          string
+	Port          int
+	MspID         string
+	Identity      []byte // PEM-encoded, base64-encoded when marshaled into the config
+	ClientTLSCert []byte // PEM-encoded, base64-encoded when marshaled into the config
+	ServerTLSCert []byte // PEM-encoded, base64-encoded when marshaled into the config
+}
+
+func applyBFTOptions(optionsMap map[string]interface{}, opts *BFTOptions) {
+	if opts == nil {
+		opts = &BFTOptions{}
+	}
+
+	if opts.RequestBatchMaxCount == 0 {
+		opts.RequestBatchMaxCount = 100
+	}
+	if opts.RequestBatchMaxBytes == "" {
+		opts.RequestBatchMaxBytes = "10MB"
+	}
+	if opts.RequestBatchMaxInterval == "" {
+		opts.RequestBatchMaxInterval = "200ms"
+	}
+	if opts.IncomingMessageBufferSize == 0 {
+		opts.IncomingMessageBufferSize = 200
+	}
+	if opts.RequestPoolSize == 0 {
+		opts.RequestPoolSize = 400
+	}
+	if opts.RequestForwardTimeout == "" {
+		opts.RequestForwardTimeout = "2s"
+	}
+	if opts.RequestComplainTimeout == "" {
+		opts.RequestComplainTimeout = "20s"
+	}
+	if opts.RequestAutoRemoveTimeout == "" {
+		opts.RequestAutoRemoveTimeout = "3m"
+	}
+	if opts.ViewChangeResendInterval == "" {
+		opts.ViewChangeResendInterval = "5s"
+	}
+	if opts.ViewChangeTimeout == "" {
+		opts.ViewChangeTimeout = "20s"
+	}
+	if opts.LeaderHeartbeatTimeout == "" {
+		opts.LeaderHeartbeatTimeout = "1m"
+	}
+	if opts.LeaderHeartbeatCount == 0 {
+		opts.LeaderHeartbeatCount = 10
+	}
+	if opts.CollectTimeout == "" {
+		opts.CollectTimeout = "1s"
+	}
+	if opts.LeaderRotation == "" {
+		opts.LeaderRotation = "ROTATION_OFF"
+	}
+	if opts.DecisionsPerLeader == 0 {
+		opts.DecisionsPerLeader = 3
+	}
+
+	optionsMap["request_batch_max_count"] = opts.RequestBatchMaxCount
+	optionsMap["request_batch_max_bytes"] = convertStorageUnit(opts.RequestBatchMaxBytes)
+	optionsMap["request_batch_max_interval"] = opts.RequestBatchMaxInterval
+	optionsMap["incoming_message_buffer_size"] = opts.IncomingMessageBufferSize
+	optionsMap["request_pool_size"] = opts.RequestPoolSize
+	optionsMap["request_forward_timeout"] = opts.RequestForwardTimeout
+	optionsMap["request_complain_timeout"] = opts.RequestComplainTimeout
+	optionsMap["request_auto_remove_timeout"] = opts.RequestAutoRemoveTimeout
+	optionsMap["view_change_resend_interval"] = opts.ViewChangeResendInterval
+	optionsMap["view_change_timeout"] = opts.ViewChangeTimeout
+	optionsMap["leader_heartbeat_timeout"] = opts.LeaderHeartbeatTimeout
+	optionsMap["leader_heartbeat_count"] = opts.LeaderHeartbeatCount
+	optionsMap["collect_timeout"] = opts.CollectTimeout
+	optionsMap["sync_on_start"] = opts.SyncOnStart
+	optionsMap["speed_up_view_change"] = opts.SpeedUpViewChange
+	optionsMap["leader_rotation"] = opts.LeaderRotation
+	optionsMap["decisions_per_leader"] = opts.DecisionsPerLeader
+}
+
+// ensureBFTOrderers makes sure the channel/orderer group carries an Orderers
+// value with a consenters list, creating it empty when absent and leaving any
+// existing entries untouched. Endpoints are added with
+// GetBFTConsenterModifiedConfig.
+func ensureBFTOrderers(values map[string]interface{}) {
+	orderersMap := getMap(values, "Orderers")
+	orderersValueMap := getMap(orderersMap, "value")
+	if _, ok := orderersValueMap["consenters"]; !ok {
+		orderersValueMap["consenters"] = []interface{}{}
+	}
+}
+
+// GetBFTConsenterModifiedConfig adds or removes a single orderer node from
+// the channel's SmartBFT consenter set, updating the orderer group's
+// Orderers value.
+func GetBFTConsenterModifiedConfig(configBytes []byte, mod Mod, consenter BFTConsenter, sysChannel bool) []byte {
+	var cfg interface{}
+
+	if configBytes != nil {
+		if sysChannel {
+			cfg = new(SystemConfig)
+		} else {
+			cfg = new(Config)
+		}
+
+		if err := json.Unmarshal(configBytes, cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var values map[string]interface{}
+	if sysChannel {
+		values = cfg.(*SystemConfig).ChannelGroup.Groups.Orderer.Values
+	} else {
+		values = cfg.(*Config).ChannelGroup.Groups.Orderer.Values
+	}
+
+	orderersMap := getMap(values, "Orderers")
+	orderersValueMap := getMap(orderersMap, "value")
+
+	consenters, _ := orderersValueMap["consenters"].([]interface{})
+	orderersValueMap["consenters"] = modifyBFTConsenters(consenters, mod, consenter)
+
+	modifiedConfigBytes, err := json.Marshal(cfg)
+	if err != nil {
+		log.Fatalln("marshal modified cfg json error:", err)
+	}
+
+	return modifiedConfigBytes
+}
+
+func modifyBFTConsenters(consenters []interface{}, mod Mod, consenter BFTConsenter) []interface{} {
+	switch mod {
+	case ModifiedModAdd:
+		return append(consenters, map[string]interface{}{
+			"host":            consenter.Host,
+			"port":            consenter.Port,
+			"msp_id":          consenter.MspID,
+			"identity":        base64.StdEncoding.EncodeToString(consenter.Identity),
+			"client_tls_cert": base64.StdEncoding.EncodeToString(consenter.ClientTLSCert),
+			"server_tls_cert": base64.StdEncoding.EncodeToString(consenter.ServerTLSCert),
+		})
+	case ModifiedModDel:
+		filtered := make([]interface{}, 0, len(consenters))
+		for _, c := range consenters {
+			entry, ok := c.(map[string]interface{})
+			if !ok {
+				filtered = append(filtered, c)
+				continue
+			}
+
+			host, _ := entry["host"].(string)
+			port, _ := entry["port"].(float64)
+			if host == consenter.Host && int(port) == consenter.Port {
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+		return filtered
+	}
+
+	return consenters
+}
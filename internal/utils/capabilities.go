@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// GetCapabilitiesModifiedConfig rewrites the Capabilities value under the
+// named group ("channel", "orderer" or "application"), e.g. to bump V2_0 to
+// V2_5 ahead of a Fabric upgrade.
+func GetCapabilitiesModifiedConfig(configBytes []byte, group string, capabilities []string, sysChannel bool) []byte {
+	var cfg interface{}
+
+	if configBytes != nil {
+		if sysChannel {
+			cfg = new(SystemConfig)
+		} else {
+			cfg = new(Config)
+		}
+
+		if err := json.Unmarshal(configBytes, cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	values := groupValues(cfg, group, sysChannel)
+
+	capabilitiesMap := getMap(values, "Capabilities")
+	valueMap := getMap(capabilitiesMap, "value")
+	capsMap := make(map[string]interface{}, len(capabilities))
+	for _, capability := range capabilities {
+		capsMap[capability] = map[string]interface{}{}
+	}
+	valueMap["capabilities"] = capsMap
+
+	modifiedConfigBytes, err := json.Marshal(cfg)
+	if err != nil {
+		log.Fatalln("marshal modified cfg json error:", err)
+	}
+
+	return modifiedConfigBytes
+}
+
+// groupValues resolves the Values map for the channel, orderer or
+// application group, for both system and application channel configs.
+func groupValues(cfg interface{}, group string, sysChannel bool) map[string]interface{} {
+	switch strings.ToLower(group) {
+	case "channel":
+		if sysChannel {
+			return cfg.(*SystemConfig).ChannelGroup.Values
+		}
+		return cfg.(*Config).ChannelGroup.Values
+	case "orderer":
+		if sysChannel {
+			return cfg.(*SystemConfig).ChannelGroup.Groups.Orderer.Values
+		}
+		return cfg.(*Config).ChannelGroup.Groups.Orderer.Values
+	case "application":
+		if sysChannel {
+			log.Fatalln("group application has no meaning on the system channel")
+		}
+		return cfg.(*Config).ChannelGroup.Groups.Application.Values
+	}
+
+	log.Fatalln("unknown group:", group)
+	return nil
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+)
+
+// RaftConsenter describes one orderer node in an etcdraft consenter set.
+type RaftConsenter struct {
+	Host          string
+	Port          int
+	ClientTLSCert []byte // PEM-encoded, base64-encoded when marshaled into the config
+	ServerTLSCert []byte // PEM-encoded, base64-encoded when marshaled into the config
+}
+
+// GetRaftConsenterModifiedConfig adds or removes a single orderer node from
+// the channel's Raft consenter set, updating both ConsensusType.metadata.consenters
+// and the orderer group's Consenters value (when present).
+//
+// This package only owns the config-editing step: fetching the newest config
+// via GetNewestConfigWithConfigBlock, calling this function, producing the
+// update envelope with GetUpdateEnvelopeProtoBytes, and collecting
+// orderer-org admin signatures with SignEnvelope/MergeEnvelopeSignatures are
+// composed by the CLI layer, which does not live in this tree and is out of
+// scope here.
+func GetRaftConsenterModifiedConfig(configBytes []byte, mod Mod, consenter RaftConsenter, sysChannel bool) []byte {
+	var cfg interface{}
+
+	if configBytes != nil {
+		if sysChannel {
+			cfg = new(SystemConfig)
+		} else {
+			cfg = new(Config)
+		}
+
+		if err := json.Unmarshal(configBytes, cfg); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var values map[string]interface{}
+	if sysChannel {
+		values = cfg.(*SystemConfig).ChannelGroup.Groups.Orderer.Values
+	} else {
+		values = cfg.(*Config).ChannelGroup.Groups.Orderer.Values
+	}
+
+	consensusTypeMap := getMap(values, "ConsensusType")
+	valueMap := getMap(consensusTypeMap, "value")
+	metadataMap := getMap(valueMap, "metadata")
+
+	consenters, _ := metadataMap["consenters"].([]interface{})
+	consenters = modifyRaftConsenters(consenters, mod, consenter)
+	metadataMap["consenters"] = consenters
+
+	if _, ok := values["Consenters"]; ok {
+		consentersMap := getMap(values, "Consenters")
+		consentersValueMap := getMap(consentersMap, "value")
+		consentersValueMap["consenters"] = consenters
+	}
+
+	modifiedConfigBytes, err := json.Marshal(cfg)
+	if err != nil {
+		log.Fatalln("marshal modified cfg json error:", err)
+	}
+
+	return modifiedConfigBytes
+}
+
+func modifyRaftConsenters(consenters []interface{}, mod Mod, consenter RaftConsenter) []interface{} {
+	switch mod {
+	case ModifiedModAdd:
+		return append(consenters, map[string]interface{}{
+			"host":            consenter.Host,
+			"port":            consenter.Port,
+			"client_tls_cert": base64.StdEncoding.EncodeToString(consenter.ClientTLSCert),
+			"server_tls_cert": base64.StdEncoding.EncodeToString(consenter.ServerTLSCert),
+		})
+	case ModifiedModDel:
+		filtered := make([]interface{}, 0, len(consenters))
+		for _, c := range consenters {
+			entry, ok := c.(map[string]interface{})
+			if !ok {
+				filtered = append(filtered, c)
+				continue
+			}
+
+			host, _ := entry["host"].(string)
+			port, _ := entry["port"].(float64)
+			if host == consenter.Host && int(port) == consenter.Port {
+				continue
+			}
+			filtered = append(filtered, entry)
+		}
+		return filtered
+	}
+
+	return consenters
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+// SignEnvelope attaches a ConfigSignature from signer to an unsigned (or
+// partially signed) config update envelope, so each org can sign on its own
+// machine before the envelope is merged and submitted.
+func SignEnvelope(envelopeBytes []byte, signer msp.SigningIdentity) ([]byte, error) {
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, errors.New(fmt.Sprintf("unmarshal common.Envelope error: %s", err))
+	}
+
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, errors.New(fmt.Sprintf("unmarshal common.Payload error: %s", err))
+	}
+
+	configUpdateEnvelope := &cb.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+		return nil, errors.New(fmt.Sprintf("unmarshal common.ConfigUpdateEnvelope error: %s", err))
+	}
+
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("serialize signing identity error: %s", err))
+	}
+
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.New(fmt.Sprintf("generate nonce error: %s", err))
+	}
+
+	signatureHeaderBytes, err := proto.Marshal(&cb.SignatureHeader{Creator: creator, Nonce: nonce})
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("marshal common.SignatureHeader error: %s", err))
+	}
+
+	signature, err := signer.Sign(append(signatureHeaderBytes, configUpdateEnvelope.ConfigUpdate...))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("sign config update error: %s", err))
+	}
+
+	configUpdateEnvelope.Signatures = append(configUpdateEnvelope.Signatures, &cb.ConfigSignature{
+		SignatureHeader: signatureHeaderBytes,
+		Signature:       signature,
+	})
+
+	if payload.Data, err = proto.Marshal(configUpdateEnvelope); err != nil {
+		return nil, errors.New(fmt.Sprintf("marshal common.ConfigUpdateEnvelope error: %s", err))
+	}
+	if envelope.Payload, err = proto.Marshal(payload); err != nil {
+		return nil, errors.New(fmt.Sprintf("marshal common.Payload error: %s", err))
+	}
+
+	return proto.Marshal(envelope)
+}
+
+// MergeEnvelopeSignatures unions the signatures collected across
+// independently-signed copies of the same config update envelope, so the
+// last party can submit a single fully-signed envelope.
+func MergeEnvelopeSignatures(envelopes ...[]byte) ([]byte, error) {
+	if len(envelopes) == 0 {
+		return nil, errors.New("merge envelope signatures error: no envelopes provided")
+	}
+
+	var baseEnvelope *cb.Envelope
+	var basePayload *cb.Payload
+	var merged *cb.ConfigUpdateEnvelope
+
+	for i, envelopeBytes := range envelopes {
+		envelope := &cb.Envelope{}
+		if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+			return nil, errors.New(fmt.Sprintf("unmarshal common.Envelope error: %s", err))
+		}
+
+		payload := &cb.Payload{}
+		if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+			return nil, errors.New(fmt.Sprintf("unmarshal common.Payload error: %s", err))
+		}
+
+		configUpdateEnvelope := &cb.ConfigUpdateEnvelope{}
+		if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+			return nil, errors.New(fmt.Sprintf("unmarshal common.ConfigUpdateEnvelope error: %s", err))
+		}
+
+		if i == 0 {
+			baseEnvelope, basePayload, merged = envelope, payload, configUpdateEnvelope
+			continue
+		}
+
+		if !bytes.Equal(merged.ConfigUpdate, configUpdateEnvelope.ConfigUpdate) {
+			return nil, errors.New("merge envelope signatures error: config update mismatch between envelopes")
+		}
+
+	nextSignature:
+		for _, sig := range configUpdateEnvelope.Signatures {
+			for _, existing := range merged.Signatures {
+				if bytes.Equal(existing.Signature, sig.Signature) {
+					continue nextSignature
+				}
+			}
+			merged.Signatures = append(merged.Signatures, sig)
+		}
+	}
+
+	mergedBytes, err := proto.Marshal(merged)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("marshal common.ConfigUpdateEnvelope error: %s", err))
+	}
+	basePayload.Data = mergedBytes
+
+	basePayloadBytes, err := proto.Marshal(basePayload)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("marshal common.Payload error: %s", err))
+	}
+	baseEnvelope.Payload = basePayloadBytes
+
+	return proto.Marshal(baseEnvelope)
+}
+
+// SaveEnvelope writes an envelope's raw proto bytes to disk so it can be
+// handed to another org out-of-band.
+func SaveEnvelope(path string, envelopeBytes []byte) error {
+	return ioutil.WriteFile(path, envelopeBytes, 0644)
+}
+
+// LoadEnvelope reads back an envelope previously written by SaveEnvelope.
+func LoadEnvelope(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
@@ -1,16 +1,19 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net/rpc"
 	"strconv"
 	"strings"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/hyperledger/fabric-config/configtx"
+	"github.com/hyperledger/fabric-config/protolator"
+	cb "github.com/hyperledger/fabric-protos-go/common"
 	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
@@ -32,10 +35,6 @@ const (
 	StateMaintenance ConsensusState = "STATE_MAINTENANCE"
 )
 
-var (
-	client *rpc.Client
-)
-
 func GetConsensusState(status string) ConsensusState {
 	switch status {
 	case "Normal":
@@ -95,57 +94,20 @@ func GetSigningIdentities(ctx context.ClientProvider, orgs []string) []msp.Signi
 	return signingIdentities
 }
 
-func InitRPCClient(address string) {
-	var err error
-
-	if client == nil {
-		client, err = rpc.DialHTTP("tcp", address)
-		if err != nil {
-			log.Fatalln("dialling rpc error:", err)
-		}
-	}
-}
-
-func protoDecode(msgName string, input []byte) ([]byte, error) {
-	return protoEncodeAndDecode("Proto.Decode", msgName, input)
-}
-
-func protoEncode(msgName string, input []byte) ([]byte, error) {
-	return protoEncodeAndDecode("Proto.Encode", msgName, input)
-}
-
-func protoEncodeAndDecode(typ, msgName string, input []byte) ([]byte, error) {
-	var reply []byte
-
-	if err := client.Call(typ, struct {
-		MsgName string
-		Input   []byte
-	}{
-		msgName,
-		input,
-	}, &reply); err != nil {
+// protoToJSON renders a proto message as the same nested JSON shape that
+// configtxlator's REST API used to produce, handling the dynamic
+// Values/Policies oneofs that plain encoding/json can't.
+func protoToJSON(msg proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := protolator.DeepMarshalJSON(&buf, msg); err != nil {
 		return nil, err
 	}
-
-	return reply, nil
+	return buf.Bytes(), nil
 }
 
-func computeUpdate(channelName string, origin, updated []byte) ([]byte, error) {
-	var reply []byte
-
-	if err := client.Call("Compute.Update", struct {
-		ChannelName string
-		Origin      []byte
-		Updated     []byte
-	}{
-		channelName,
-		origin,
-		updated,
-	}, &reply); err != nil {
-		return nil, err
-	}
-
-	return reply, nil
+// jsonToProto is the inverse of protoToJSON.
+func jsonToProto(input []byte, msg proto.Message) error {
+	return protolator.DeepUnmarshalJSON(bytes.NewReader(input), msg)
 }
 
 func GetStdConfigBytes(mspID string, configBytes []byte) []byte {
@@ -163,14 +125,10 @@ func GetNewestConfigWithConfigBlock(resMgmt *resmgmt.Client, channelName string,
 	if err != nil {
 		log.Fatalln(err)
 	}
-	blockPBBytes, err := proto.Marshal(blockPB)
-	if err != nil {
-		log.Fatalln(err)
-	}
 
-	blockBytes, err := protoDecode("common.Block", blockPBBytes)
+	blockBytes, err := protoToJSON(blockPB)
 	if err != nil {
-		log.Fatalln("proto decode common.Block error:", err)
+		log.Fatalln("marshal common.Block to json error:", err)
 	}
 
 	var block interface{}
@@ -332,6 +290,7 @@ func GetChannelParamsModifiedConfig(configBytes []byte,
 func GetChannelConsensusStateModifiedConfig(configBytes []byte, consensusState ConsensusState, consensusType string,
 	consensusOptionElectionTick, consensusOptionHeartbeatTick, consensusOptionMaxInflightBlocks int,
 	consensusOptionSnapshotIntervalSize, consensusOptionTickInterval string,
+	bftOptions *BFTOptions,
 	sysChannel bool) []byte {
 	var cfg interface{}
 
@@ -362,6 +321,10 @@ func GetChannelConsensusStateModifiedConfig(configBytes []byte, consensusState C
 	switch {
 	case consensusState != "":
 		valueMap["state"] = consensusState
+	case consensusType == "BFT":
+		valueMap["type"] = consensusType
+		applyBFTOptions(optionsMap, bftOptions)
+		ensureBFTOrderers(values)
 	case consensusType != "":
 		if consensusType == "etcdraft" {
 			if consensusOptionElectionTick == 0 {
@@ -407,34 +370,42 @@ func GetChannelConsensusStateModifiedConfig(configBytes []byte, consensusState C
 }
 
 func GetUpdateEnvelopeProtoBytes(configBytes, modifiedConfigBytes []byte, channelName string) []byte {
-	configPBBytes, err := protoEncode("common.Config", configBytes)
-	if err != nil {
-		log.Fatalln("proto encode common.Config error:", err)
+	origConfig := &cb.Config{}
+	if err := jsonToProto(configBytes, origConfig); err != nil {
+		log.Fatalln("unmarshal common.Config json error:", err)
 	}
 
-	// get modified config.pb
-	modifiedConfigPBBytes, err := protoEncode("common.Config", modifiedConfigBytes)
-	if err != nil {
-		log.Fatalln("proto encode common.Config error:", err)
+	modifiedConfig := &cb.Config{}
+	if err := jsonToProto(modifiedConfigBytes, modifiedConfig); err != nil {
+		log.Fatalln("unmarshal common.Config json error:", err)
 	}
 
 	// get update.pb
-	updateConfigPBBytes, err := computeUpdate(channelName, configPBBytes, modifiedConfigPBBytes)
+	updateConfigPBBytes, err := configtx.ComputeMarshaledUpdate(channelName, origConfig, modifiedConfig)
 	if err != nil {
 		log.Fatalln("compute update error:", err)
 	}
 
+	updateConfig := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(updateConfigPBBytes, updateConfig); err != nil {
+		log.Fatalln("unmarshal common.ConfigUpdate proto error:", err)
+	}
+
 	// get update.json
-	updateConfigBytes, err := protoDecode("common.ConfigUpdate", updateConfigPBBytes)
+	updateConfigBytes, err := protoToJSON(updateConfig)
 	if err != nil {
-		log.Fatalln("proto decode common.ConfigUpdate error:", err)
+		log.Fatalln("marshal common.ConfigUpdate json error:", err)
 	}
 	updateEnvelopeBytes := GetStdUpdateEnvelopBytes(channelName, updateConfigBytes)
 
 	// get update.pb
-	updateEnvelopePBBytes, err := protoEncode("common.Envelope", updateEnvelopeBytes)
+	updateEnvelope := &cb.Envelope{}
+	if err := jsonToProto(updateEnvelopeBytes, updateEnvelope); err != nil {
+		log.Fatalln("unmarshal common.Envelope json error:", err)
+	}
+	updateEnvelopePBBytes, err := proto.Marshal(updateEnvelope)
 	if err != nil {
-		log.Fatalln("proto encode common.Envelope error:", err)
+		log.Fatalln("marshal common.Envelope proto error:", err)
 	}
 
 	return updateEnvelopePBBytes